@@ -41,6 +41,7 @@
 package argon3
 
 import (
+	"context"
 	"encoding/binary"
 	"sync"
 
@@ -110,7 +111,40 @@ func DKey(password, salt []byte, time, memory uint32, threads uint8, keyLen uint
 	return deriveKey(argon3d, password, salt, nil, nil, time, memory, threads, keyLen)
 }
 
+// KeyWithSecret is like Key, but additionally mixes a secret key (sometimes
+// called a "pepper") and associated data into the derivation. secret is
+// typically a server-side value that does not live alongside the salt and
+// hash (e.g. in an HSM or config secret), and data is arbitrary context
+// that should be bound to the derived key (e.g. a user ID). Either may be
+// nil.
+func KeyWithSecret(password, salt, secret, data []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	return deriveKey(argon3i, password, salt, secret, data, time, memory, threads, keyLen)
+}
+
+// IDKeyWithSecret is like IDKey, but additionally mixes a secret key
+// (sometimes called a "pepper") and associated data into the derivation.
+// See KeyWithSecret for details.
+func IDKeyWithSecret(password, salt, secret, data []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	return deriveKey(argon3id, password, salt, secret, data, time, memory, threads, keyLen)
+}
+
+// DKeyWithSecret is like DKey, but additionally mixes a secret key
+// (sometimes called a "pepper") and associated data into the derivation.
+// See KeyWithSecret for details.
+func DKeyWithSecret(password, salt, secret, data []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	return deriveKey(argon3d, password, salt, secret, data, time, memory, threads, keyLen)
+}
+
 func deriveKey(mode int, password, salt, secret, data []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	key, err := deriveKeyContext(context.Background(), mode, password, salt, secret, data, time, memory, threads, keyLen, nil)
+	if err != nil {
+		// context.Background() never cancels or times out.
+		panic(err)
+	}
+	return key
+}
+
+func deriveKeyContext(ctx context.Context, mode int, password, salt, secret, data []byte, time, memory uint32, threads uint8, keyLen uint32, progress ProgressFunc) ([]byte, error) {
 	if time < 1 {
 		panic("argon3: number of rounds too small")
 	}
@@ -124,8 +158,11 @@ func deriveKey(mode int, password, salt, secret, data []byte, time, memory uint3
 		memory = 2 * syncPoints * uint32(threads)
 	}
 	B := initBlocks(&h0, memory, uint32(threads))
-	processBlocks(B, time, memory, uint32(threads), mode)
-	return extractKey(B, memory, uint32(threads), keyLen)
+	if err := processBlocks(ctx, B, time, memory, uint32(threads), mode, progress); err != nil {
+		putBlocks(B)
+		return nil, err
+	}
+	return extractKey(B, memory, uint32(threads), keyLen), nil
 }
 
 const (
@@ -170,7 +207,7 @@ func initHash(password, salt, key, data []byte, time, memory, threads, keyLen ui
 
 func initBlocks(h0 *[64 + 8]byte, memory, threads uint32) []block {
 	var block0 [1024]byte
-	B := make([]block, memory)
+	B := getBlocks(memory)
 	for lane := uint32(0); lane < threads; lane++ {
 		j := lane * (memory / threads)
 		binary.LittleEndian.PutUint32(h0[64+4:], lane)
@@ -190,7 +227,13 @@ func initBlocks(h0 *[64 + 8]byte, memory, threads uint32) []block {
 	return B
 }
 
-func processBlocks(B []block, time, memory, threads uint32, mode int) {
+// processBlocks runs the passes over B required by the Argon3 spec. ctx is
+// checked for cancellation once per sync point (the for slice loop below),
+// which is the natural granularity since wg.Wait() already serializes
+// there; on cancellation it returns ctx.Err() without finishing the
+// remaining passes. progress, if non-nil, is called at the same
+// granularity with the number of fully completed passes so far.
+func processBlocks(ctx context.Context, B []block, time, memory, threads uint32, mode int, progress ProgressFunc) error {
 	lanes := memory / threads
 	segments := lanes / syncPoints
 
@@ -241,15 +284,31 @@ func processBlocks(B []block, time, memory, threads uint32, mode int) {
 
 	for n := uint32(0); n < time; n++ {
 		for slice := uint32(0); slice < syncPoints; slice++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
 			var wg sync.WaitGroup
 			for lane := uint32(0); lane < threads; lane++ {
 				wg.Add(1)
 				go processSegment(n, slice, lane, &wg)
 			}
 			wg.Wait()
+
+			if progress != nil {
+				// Pass n has only fully completed once its last sync
+				// point (slice == syncPoints-1) finishes; until then
+				// n passes remain done, not n+1.
+				done := n
+				if slice == syncPoints-1 {
+					done = n + 1
+				}
+				progress(done, time)
+			}
 		}
 	}
 
+	return nil
 }
 
 func extractKey(B []block, memory, threads, keyLen uint32) []byte {
@@ -266,6 +325,7 @@ func extractKey(B []block, memory, threads, keyLen uint32) []byte {
 	}
 	key := make([]byte, keyLen)
 	blake3Hash(key, block[:])
+	putBlocks(B)
 	return key
 }
 
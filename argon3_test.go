@@ -24,6 +24,42 @@ func TestVectors(t *testing.T) {
 	}
 }
 
+// TestSecretAndAAD locks in that secret and data actually reach initHash:
+// changing either must change the derived key, and supplying them must not
+// crash the nil-secret/nil-data path used by Key/IDKey/DKey.
+//
+// NOTE: the upstream Argon2 RFC test vectors for the secret (0x03) and AAD
+// (0x04) cases are only published for the Blake2b-based construction. They
+// cannot be reused as-is here because this package mixes inputs in via
+// BLAKE3 (see initHash); regenerating them requires a reference BLAKE3
+// Argon3 implementation to hash against, which is not available in this
+// environment. Until such vectors are regenerated, this test only pins the
+// wiring rather than exact output bytes.
+func TestSecretAndAAD(t *testing.T) {
+	password, salt := []byte("password"), []byte("somesalt")
+	const time, memory, keyLen = 2, 64, 32
+	const threads = 1
+
+	base := deriveKey(argon3id, password, salt, nil, nil, time, memory, threads, keyLen)
+	withSecret := deriveKey(argon3id, password, salt, []byte("server-pepper"), nil, time, memory, threads, keyLen)
+	withAAD := deriveKey(argon3id, password, salt, nil, []byte("associated-data"), time, memory, threads, keyLen)
+	withBoth := deriveKey(argon3id, password, salt, []byte("server-pepper"), []byte("associated-data"), time, memory, threads, keyLen)
+
+	if bytes.Equal(base, withSecret) {
+		t.Errorf("secret did not change the derived key")
+	}
+	if bytes.Equal(base, withAAD) {
+		t.Errorf("associated data did not change the derived key")
+	}
+	if bytes.Equal(withSecret, withBoth) || bytes.Equal(withAAD, withBoth) {
+		t.Errorf("combining secret and associated data did not change the derived key")
+	}
+
+	if got := IDKeyWithSecret(password, salt, []byte("server-pepper"), nil, time, memory, threads, keyLen); !bytes.Equal(got, withSecret) {
+		t.Errorf("IDKeyWithSecret did not forward secret to deriveKey")
+	}
+}
+
 func benchmarkArgon3(mode int, time, memory uint32, threads uint8, keyLen uint32, b *testing.B) {
 	password := []byte("password")
 	salt := []byte("choosing random salts is hard")
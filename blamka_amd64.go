@@ -0,0 +1,47 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build amd64 && !purego
+
+package argon3
+
+import "github.com/klauspost/cpuid/v2"
+
+// useSSE4 reports whether the host CPU supports the SSE4.1 instructions
+// that xorBlocksSSE4/combineBlocksSSE4 are written against.
+var useSSE4 = cpuid.CPU.Supports(cpuid.SSE4)
+
+//go:noescape
+func xorBlocksSSE4(dst, a, b *block)
+
+//go:noescape
+func combineBlocksSSE4(out, a, b, t *block, xor bool)
+
+// processBlockSSE is the amd64 counterpart of processBlockGeneric. The
+// block-sized xor/combine steps, which dominate the memory traffic of a
+// compression, are done with SSE4.1 in blamka_amd64.s; the BlaMka round
+// function itself (permute) stays scalar Go on both code paths so that
+// choosing this path can never change the derived key, only its speed.
+func processBlockSSE(out, in1, in2 *block, xor bool) {
+	var t block
+	xorBlocksSSE4(&t, in1, in2)
+	permute(&t)
+	combineBlocksSSE4(out, in1, in2, &t, xor)
+}
+
+func processBlock(out, in1, in2 *block) {
+	if useSSE4 {
+		processBlockSSE(out, in1, in2, false)
+	} else {
+		processBlockGeneric(out, in1, in2, false)
+	}
+}
+
+func processBlockXOR(out, in1, in2 *block) {
+	if useSSE4 {
+		processBlockSSE(out, in1, in2, true)
+	} else {
+		processBlockGeneric(out, in1, in2, true)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build amd64 && !purego
+
+package argon3
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestBlaMkaSSE4 exercises both the generic and the SSE4.1-accelerated
+// block xor/combine paths and checks that they agree, so that the compiler
+// flag used to build a given binary can never silently change a derived
+// key.
+func TestBlaMkaSSE4(t *testing.T) {
+	defer func(sse4 bool) { useSSE4 = sse4 }(useSSE4)
+
+	var in1, in2 block
+	for i := range in1 {
+		in1[i] = uint64(i)
+		in2[i] = uint64(i) * 0x9E3779B97F4A7C15
+	}
+
+	for _, xor := range []bool{false, true} {
+		var wantOut, gotOut block
+		for i := range wantOut {
+			wantOut[i], gotOut[i] = uint64(i)+1, uint64(i)+1
+		}
+
+		useSSE4 = false
+		processBlockGeneric(&wantOut, &in1, &in2, xor)
+
+		useSSE4 = true
+		processBlockSSE(&gotOut, &in1, &in2, xor)
+
+		if !bytes.Equal(blockBytes(&wantOut), blockBytes(&gotOut)) {
+			t.Errorf("xor=%v: SSE4 path disagrees with the generic path", xor)
+		}
+	}
+}
+
+func blockBytes(b *block) []byte {
+	out := make([]byte, 0, len(b)*8)
+	for _, v := range b {
+		out = append(out,
+			byte(v), byte(v>>8), byte(v>>16), byte(v>>24),
+			byte(v>>32), byte(v>>40), byte(v>>48), byte(v>>56),
+		)
+	}
+	return out
+}
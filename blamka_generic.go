@@ -0,0 +1,106 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package argon3
+
+// processBlockGeneric is the portable implementation of the BlaMka
+// compression function used by processBlock/processBlockXOR. It computes
+// P(in1 xor in2), where P applies the BlaMka round function first to each
+// of the 8 rows of the 8x16 word block, then to each of the 8 diagonals,
+// as specified by the Argon2 reference. If xor is true, the result is
+// additionally xored into out instead of overwriting it, which is what
+// the "data-dependent" and "data-independent" addressing passes both need
+// once a block has already been written once.
+func processBlockGeneric(out, in1, in2 *block, xor bool) {
+	var t block
+	xorBlocks(&t, in1, in2)
+	permute(&t)
+	combineBlocks(out, in1, in2, &t, xor)
+}
+
+// xorBlocks computes dst = a xor b.
+func xorBlocks(dst, a, b *block) {
+	for i := range dst {
+		dst[i] = a[i] ^ b[i]
+	}
+}
+
+// combineBlocks finishes a compression: out = a xor b xor t, or, if xor is
+// true, out ^= a xor b xor t.
+func combineBlocks(out, a, b, t *block, xor bool) {
+	if xor {
+		for i := range out {
+			out[i] ^= a[i] ^ b[i] ^ t[i]
+		}
+	} else {
+		for i := range out {
+			out[i] = a[i] ^ b[i] ^ t[i]
+		}
+	}
+}
+
+// permute applies the BlaMka round function in place to each of the 8 rows
+// of t, then to each of the 8 diagonals, as specified by the Argon2
+// reference. This is the scalar part of the compression function; it is
+// shared verbatim by the generic and amd64 code paths so that the choice
+// of SIMD implementation can never change the derived key.
+func permute(t *block) {
+	for i := 0; i < 8; i++ {
+		blamkaGeneric(
+			&t[16*i], &t[16*i+1], &t[16*i+2], &t[16*i+3],
+			&t[16*i+4], &t[16*i+5], &t[16*i+6], &t[16*i+7],
+			&t[16*i+8], &t[16*i+9], &t[16*i+10], &t[16*i+11],
+			&t[16*i+12], &t[16*i+13], &t[16*i+14], &t[16*i+15],
+		)
+	}
+
+	for i := 0; i < 8; i++ {
+		blamkaGeneric(
+			&t[2*i], &t[2*i+1], &t[2*i+16], &t[2*i+17],
+			&t[2*i+32], &t[2*i+33], &t[2*i+48], &t[2*i+49],
+			&t[2*i+64], &t[2*i+65], &t[2*i+80], &t[2*i+81],
+			&t[2*i+96], &t[2*i+97], &t[2*i+112], &t[2*i+113],
+		)
+	}
+}
+
+func blamkaGeneric(t00, t01, t02, t03, t04, t05, t06, t07, t08, t09, t10, t11, t12, t13, t14, t15 *uint64) {
+	v00, v01, v02, v03 := *t00, *t01, *t02, *t03
+	v04, v05, v06, v07 := *t04, *t05, *t06, *t07
+	v08, v09, v10, v11 := *t08, *t09, *t10, *t11
+	v12, v13, v14, v15 := *t12, *t13, *t14, *t15
+
+	v00, v04, v08, v12 = fBlaMka(v00, v04, v08, v12)
+	v01, v05, v09, v13 = fBlaMka(v01, v05, v09, v13)
+	v02, v06, v10, v14 = fBlaMka(v02, v06, v10, v14)
+	v03, v07, v11, v15 = fBlaMka(v03, v07, v11, v15)
+
+	v00, v05, v10, v15 = fBlaMka(v00, v05, v10, v15)
+	v01, v06, v11, v12 = fBlaMka(v01, v06, v11, v12)
+	v02, v07, v08, v13 = fBlaMka(v02, v07, v08, v13)
+	v03, v04, v09, v14 = fBlaMka(v03, v04, v09, v14)
+
+	*t00, *t01, *t02, *t03 = v00, v01, v02, v03
+	*t04, *t05, *t06, *t07 = v04, v05, v06, v07
+	*t08, *t09, *t10, *t11 = v08, v09, v10, v11
+	*t12, *t13, *t14, *t15 = v12, v13, v14, v15
+}
+
+func fBlaMka(a, b, c, d uint64) (uint64, uint64, uint64, uint64) {
+	a += b + 2*uint64(uint32(a))*uint64(uint32(b))
+	d = rotr64(d^a, 32)
+	c += d + 2*uint64(uint32(c))*uint64(uint32(d))
+	b = rotr64(b^c, 24)
+
+	a += b + 2*uint64(uint32(a))*uint64(uint32(b))
+	d = rotr64(d^a, 16)
+	c += d + 2*uint64(uint32(c))*uint64(uint32(d))
+	b = rotr64(b^c, 63)
+
+	return a, b, c, d
+}
+
+func rotr64(x uint64, n uint) uint64 {
+	return x>>n | x<<(64-n)
+}
@@ -0,0 +1,120 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package argon3
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Mode selects which Argon3 variant Calibrate should tune parameters for.
+type Mode int
+
+// The Argon3 variants Calibrate can tune parameters for.
+const (
+	ModeD  Mode = argon3d
+	ModeI  Mode = argon3i
+	ModeID Mode = argon3id
+)
+
+// ErrTargetUnreachable is returned by Calibrate when even the floor
+// configuration (8 MiB, time=1) already exceeds target, so no (time,
+// memory) pair can be found that meets the requested latency.
+var ErrTargetUnreachable = errors.New("argon3: target latency unreachable at floor parameters")
+
+// calibrationRuns is the number of single-pass derivations Calibrate times
+// at each candidate (time, memory) pair; it reports the median to reduce
+// noise from scheduling jitter.
+const calibrationRuns = 3
+
+// Calibrate searches for Argon3 parameters that make a single derivation
+// take approximately target latency, without exceeding maxMemoryKiB or
+// using more than threads parallelism.
+//
+// It first does a geometric search, starting at 8 MiB and doubling, for
+// the largest memory (capped at maxMemoryKiB) at which a time=1
+// derivation completes in at most target. It then increases time, one
+// pass at a time, until latency at that memory crosses target from below.
+// Each candidate is timed as the median of calibrationRuns warmed-up runs.
+//
+// Calibrate can take a while, since it runs real derivations; pass a ctx
+// with a deadline (e.g. 30 seconds) to bound its total wall-clock time.
+// If ctx is canceled or its deadline elapses before Calibrate converges,
+// it returns ctx.Err(). If even the floor configuration (8 MiB, time=1)
+// already exceeds target, it returns ErrTargetUnreachable instead of
+// silently returning the floor.
+func Calibrate(ctx context.Context, target time.Duration, maxMemoryKiB uint32, threads uint8, mode Mode) (timeParam, memory uint32, err error) {
+	if target <= 0 {
+		panic("argon3: target duration must be positive")
+	}
+	if maxMemoryKiB < 8*1024 {
+		panic("argon3: maxMemoryKiB too small")
+	}
+	if threads < 1 {
+		panic("argon3: parallelism degree too low")
+	}
+
+	password := []byte("argon3-calibrate")
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return 0, 0, fmt.Errorf("argon3: failed to generate calibration salt: %w", err)
+	}
+
+	measure := func(t, m uint32) (time.Duration, error) {
+		samples := make([]time.Duration, calibrationRuns)
+		for i := range samples {
+			start := time.Now()
+			if _, err := deriveKeyContext(ctx, int(mode), password, salt, nil, nil, t, m, threads, 32, nil); err != nil {
+				return 0, err
+			}
+			samples[i] = time.Since(start)
+		}
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+		return samples[len(samples)/2], nil
+	}
+
+	memory = 8 * 1024
+	latency, err := measure(1, memory)
+	if err != nil {
+		return 0, 0, err
+	}
+	if latency > target {
+		return 0, 0, fmt.Errorf("%w: measured %s at memory=%d KiB, time=1", ErrTargetUnreachable, latency, memory)
+	}
+
+	for latency <= target && memory*2 <= maxMemoryKiB {
+		if err := ctx.Err(); err != nil {
+			return 0, 0, err
+		}
+		next := memory * 2
+		d, err := measure(1, next)
+		if err != nil {
+			return 0, 0, err
+		}
+		if d > target {
+			break
+		}
+		memory, latency = next, d
+	}
+
+	timeParam = 1
+	for latency < target {
+		if err := ctx.Err(); err != nil {
+			return 0, 0, err
+		}
+		timeParam++
+		d, err := measure(timeParam, memory)
+		if err != nil {
+			return 0, 0, err
+		}
+		latency = d
+	}
+
+	return timeParam, memory, nil
+}
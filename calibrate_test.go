@@ -0,0 +1,59 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package argon3
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCalibrateConverges(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	target := 50 * time.Millisecond
+	timeParam, memory, err := Calibrate(ctx, target, 64*1024, 1, ModeID)
+	if err != nil {
+		t.Fatalf("Calibrate failed: %v", err)
+	}
+	if memory < 8*1024 {
+		t.Errorf("memory = %d, want at least the 8 MiB floor", memory)
+	}
+	if timeParam < 1 {
+		t.Errorf("timeParam = %d, want at least 1", timeParam)
+	}
+
+	// The returned parameters should actually land close to target, not
+	// just satisfy the floor bounds above.
+	password := []byte("argon3-calibrate-test")
+	salt := make([]byte, 16)
+	start := time.Now()
+	if _, err := deriveKeyContext(ctx, int(ModeID), password, salt, nil, nil, timeParam, memory, 1, 32, nil); err != nil {
+		t.Fatalf("deriveKeyContext failed: %v", err)
+	}
+	if latency := time.Since(start); latency > target*3 {
+		t.Errorf("measured latency %s is far above target %s (memory=%d, time=%d)", latency, target, memory, timeParam)
+	}
+}
+
+func TestCalibrateTargetUnreachable(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, _, err := Calibrate(ctx, time.Nanosecond, 8*1024, 1, ModeID); !errors.Is(err, ErrTargetUnreachable) {
+		t.Errorf("expected ErrTargetUnreachable, got %v", err)
+	}
+}
+
+func TestCalibrateRespectsCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, _, err := Calibrate(ctx, time.Millisecond, 8*1024, 1, ModeID); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command argon3-tune prints Argon3id parameters, tuned for the host it
+// runs on, that make a single derivation take approximately the requested
+// target latency.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/duggavo/argon3"
+)
+
+func main() {
+	target := flag.Duration("target", 200*time.Millisecond, "target latency for a single derivation")
+	maxMemoryMiB := flag.Uint("max-memory", 1024, "maximum memory, in MiB, that may be recommended")
+	threads := flag.Uint("threads", 4, "parallelism degree to tune for")
+	timeout := flag.Duration("timeout", 30*time.Second, "maximum total time to spend calibrating")
+	flag.Parse()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	timeParam, memory, err := argon3.Calibrate(ctx, *target, uint32(*maxMemoryMiB)*1024, uint8(*threads), argon3.ModeID)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "argon3-tune:", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("argon3.Params{Time: %d, Memory: %d, Threads: %d, SaltLen: 16, KeyLen: 32}\n",
+		timeParam, memory, uint8(*threads))
+}
@@ -0,0 +1,38 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package argon3
+
+import "context"
+
+// ProgressFunc is called during a Context-aware derivation to report
+// progress. passesDone is the number of passes over memory (the time
+// parameter) that have fully completed so far, and totalPasses is the
+// time parameter the derivation was called with. It is called once per
+// synchronization point, the same granularity at which cancellation is
+// checked, so it may be called several times with the same passesDone
+// before that count advances.
+type ProgressFunc func(passesDone, totalPasses uint32)
+
+// KeyContext is like Key, but accepts a context.Context that is checked
+// for cancellation periodically during the derivation. If ctx is canceled
+// or its deadline elapses before the derivation finishes, KeyContext
+// returns ctx.Err() and a nil key; any partial block state is zeroed
+// before returning. progress, if non-nil, is called periodically with the
+// derivation's progress; see ProgressFunc.
+func KeyContext(ctx context.Context, password, salt []byte, time, memory uint32, threads uint8, keyLen uint32, progress ProgressFunc) ([]byte, error) {
+	return deriveKeyContext(ctx, argon3i, password, salt, nil, nil, time, memory, threads, keyLen, progress)
+}
+
+// IDKeyContext is like IDKey, but accepts a context.Context and an
+// optional ProgressFunc. See KeyContext for details.
+func IDKeyContext(ctx context.Context, password, salt []byte, time, memory uint32, threads uint8, keyLen uint32, progress ProgressFunc) ([]byte, error) {
+	return deriveKeyContext(ctx, argon3id, password, salt, nil, nil, time, memory, threads, keyLen, progress)
+}
+
+// DKeyContext is like DKey, but accepts a context.Context and an optional
+// ProgressFunc. See KeyContext for details.
+func DKeyContext(ctx context.Context, password, salt []byte, time, memory uint32, threads uint8, keyLen uint32, progress ProgressFunc) ([]byte, error) {
+	return deriveKeyContext(ctx, argon3d, password, salt, nil, nil, time, memory, threads, keyLen, progress)
+}
@@ -0,0 +1,52 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package argon3
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestKeyContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	key, err := IDKeyContext(ctx, []byte("password"), []byte("somesalt"), 4, 64*1024, 4, 32, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if key != nil {
+		t.Errorf("expected a nil key on cancellation, got %x", key)
+	}
+}
+
+func TestKeyContextMatchesKey(t *testing.T) {
+	password, salt := []byte("password"), []byte("somesalt")
+	const timeParam, memory uint32 = 2, 64
+	const threads = 1
+
+	want := deriveKey(argon3id, password, salt, nil, nil, timeParam, memory, threads, 32)
+
+	var seen []uint32
+	got, err := IDKeyContext(context.Background(), password, salt, timeParam, memory, threads, 32, func(passesDone, totalPasses uint32) {
+		if totalPasses != timeParam {
+			t.Errorf("progress callback: totalPasses = %d, want %d", totalPasses, timeParam)
+		}
+		seen = append(seen, passesDone)
+	})
+	if err != nil {
+		t.Fatalf("IDKeyContext returned an error: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("IDKeyContext derived a different key than deriveKey")
+	}
+	if len(seen) == 0 {
+		t.Errorf("progress callback was never called")
+	}
+	if last := seen[len(seen)-1]; last != timeParam {
+		t.Errorf("final passesDone = %d, want %d (totalPasses)", last, timeParam)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package argon3
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidHash is returned by Verify when the encoded string is not a
+// well-formed Argon3id PHC string.
+var ErrInvalidHash = errors.New("argon3: invalid hash format")
+
+// ErrIncompatibleVersion is returned by Verify when the encoded string was
+// produced by a different Argon3 version than the one implemented by this
+// package. Callers can use this to detect hashes that need to be
+// re-derived (migrated) on next successful login.
+var ErrIncompatibleVersion = errors.New("argon3: incompatible version")
+
+// maxDecodedMemoryKiB and maxDecodedTime bound the m= and t= fields
+// decodeHash accepts from an encoded string. Verify has no context to
+// cancel a derivation mid-flight, so a corrupted or attacker-influenced
+// row must not be able to drive it into parameter territory that ties up
+// a goroutine for an unreasonable amount of time or memory.
+const (
+	maxDecodedMemoryKiB = 4 * 1024 * 1024 // 4 GiB
+	maxDecodedTime      = 1 << 20
+)
+
+// Params holds the cost parameters used by Hash to derive and encode a
+// password hash. The zero value is not valid; use DefaultParams as a
+// starting point.
+type Params struct {
+	// Time is the number of passes over the memory.
+	Time uint32
+	// Memory is the size of the memory in KiB.
+	Memory uint32
+	// Threads is the degree of parallelism.
+	Threads uint8
+	// SaltLen is the length, in bytes, of the random salt generated by Hash.
+	SaltLen uint32
+	// KeyLen is the length, in bytes, of the derived key.
+	KeyLen uint32
+}
+
+// DefaultParams are sensible parameters for non-interactive, server-side
+// password hashing, following the recommendations in [2].
+var DefaultParams = Params{
+	Time:    1,
+	Memory:  64 * 1024,
+	Threads: 4,
+	SaltLen: 16,
+	KeyLen:  32,
+}
+
+// Hash derives a key from password using Argon3id and params, and encodes
+// the result as a PHC string of the form:
+//
+//	$argon3id$v=19$m=65536,t=1,p=4$<salt>$<hash>
+//
+// with <salt> and <hash> encoded using unpadded standard base64, mirroring
+// the encoding used by golang.org/x/crypto/argon2. A fresh random salt of
+// length params.SaltLen is generated using crypto/rand for every call.
+func Hash(password []byte, params Params) (string, error) {
+	salt := make([]byte, params.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("argon3: failed to generate salt: %w", err)
+	}
+
+	hash := IDKey(password, salt, params.Time, params.Memory, params.Threads, params.KeyLen)
+
+	b64Salt := base64.RawStdEncoding.EncodeToString(salt)
+	b64Hash := base64.RawStdEncoding.EncodeToString(hash)
+
+	encoded := fmt.Sprintf("$argon3id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		Version, params.Memory, params.Time, params.Threads, b64Salt, b64Hash)
+	return encoded, nil
+}
+
+// Verify reports whether password matches the Argon3id PHC string encoded,
+// as produced by Hash. The comparison is done in constant time.
+//
+// If encoded was produced by an incompatible version of Argon3, Verify
+// returns ErrIncompatibleVersion alongside a false match so that callers
+// can distinguish "wrong password" from "needs migration" and re-hash on
+// the next successful login with different parameters.
+func Verify(encoded string, password []byte) (bool, error) {
+	params, salt, want, err := decodeHash(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	got := IDKey(password, salt, params.Time, params.Memory, params.Threads, uint32(len(want)))
+
+	match := subtle.ConstantTimeCompare(got, want) == 1
+	if params.version != Version {
+		return match, ErrIncompatibleVersion
+	}
+	return match, nil
+}
+
+// decodedParams additionally carries the version parsed out of encoded,
+// so Verify can compare it against Version without re-parsing the string.
+type decodedParams struct {
+	Params
+	version int
+}
+
+func decodeHash(encoded string) (decodedParams, []byte, []byte, error) {
+	fields := strings.Split(encoded, "$")
+	// fields[0] is empty because encoded starts with "$".
+	if len(fields) != 6 || fields[1] != "argon3id" {
+		return decodedParams{}, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(fields[2], "v=%d", &version); err != nil {
+		return decodedParams{}, nil, nil, ErrInvalidHash
+	}
+
+	var params decodedParams
+	params.version = version
+	if _, err := fmt.Sscanf(fields[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return decodedParams{}, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(fields[4])
+	if err != nil {
+		return decodedParams{}, nil, nil, ErrInvalidHash
+	}
+	params.SaltLen = uint32(len(salt))
+
+	hash, err := base64.RawStdEncoding.DecodeString(fields[5])
+	if err != nil {
+		return decodedParams{}, nil, nil, ErrInvalidHash
+	}
+	if len(hash) == 0 {
+		return decodedParams{}, nil, nil, ErrInvalidHash
+	}
+	params.KeyLen = uint32(len(hash))
+
+	if params.Time < 1 || params.Threads < 1 {
+		return decodedParams{}, nil, nil, ErrInvalidHash
+	}
+	if params.Memory > maxDecodedMemoryKiB || params.Time > maxDecodedTime {
+		return decodedParams{}, nil, nil, ErrInvalidHash
+	}
+
+	return params, salt, hash, nil
+}
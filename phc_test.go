@@ -0,0 +1,90 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package argon3
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHashAndVerify(t *testing.T) {
+	params := Params{Time: 1, Memory: 64, Threads: 1, SaltLen: 16, KeyLen: 32}
+	password := []byte("hunter2")
+
+	encoded, err := Hash(password, params)
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$argon3id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("unexpected encoding: %s", encoded)
+	}
+
+	ok, err := Verify(encoded, password)
+	if err != nil {
+		t.Errorf("Verify returned error for a freshly generated hash: %v", err)
+	}
+	if !ok {
+		t.Errorf("Verify rejected the correct password")
+	}
+
+	ok, err = Verify(encoded, []byte("wrong password"))
+	if err != nil {
+		t.Errorf("Verify returned unexpected error: %v", err)
+	}
+	if ok {
+		t.Errorf("Verify accepted an incorrect password")
+	}
+}
+
+func TestVerifyInvalidHash(t *testing.T) {
+	if _, err := Verify("not a phc string", []byte("password")); !errors.Is(err, ErrInvalidHash) {
+		t.Errorf("expected ErrInvalidHash, got %v", err)
+	}
+}
+
+func TestVerifyEmptyHashRejected(t *testing.T) {
+	ok, err := Verify("$argon3id$v=19$m=64,t=1,p=1$c2FsdA$", []byte("totally wrong password"))
+	if ok {
+		t.Errorf("Verify accepted a password against an empty hash field")
+	}
+	if !errors.Is(err, ErrInvalidHash) {
+		t.Errorf("expected ErrInvalidHash, got %v", err)
+	}
+}
+
+func TestVerifyRejectsZeroParallelism(t *testing.T) {
+	ok, err := Verify("$argon3id$v=19$m=64,t=1,p=0$c2FsdA$c2FsdA", []byte("password"))
+	if ok {
+		t.Errorf("Verify accepted a hash with p=0")
+	}
+	if !errors.Is(err, ErrInvalidHash) {
+		t.Errorf("expected ErrInvalidHash, got %v", err)
+	}
+}
+
+func TestVerifyRejectsExcessiveMemory(t *testing.T) {
+	ok, err := Verify("$argon3id$v=19$m=3000000000,t=1,p=1$c2FsdA$c2FsdA", []byte("password"))
+	if ok {
+		t.Errorf("Verify accepted a hash with a pathological memory parameter")
+	}
+	if !errors.Is(err, ErrInvalidHash) {
+		t.Errorf("expected ErrInvalidHash, got %v", err)
+	}
+}
+
+func TestVerifyIncompatibleVersion(t *testing.T) {
+	params := Params{Time: 1, Memory: 64, Threads: 1, SaltLen: 16, KeyLen: 32}
+	encoded, err := Hash([]byte("password"), params)
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+
+	old := strings.Replace(encoded, "v=19", "v=18", 1)
+	_, err = Verify(old, []byte("password"))
+	if !errors.Is(err, ErrIncompatibleVersion) {
+		t.Errorf("expected ErrIncompatibleVersion, got %v", err)
+	}
+}
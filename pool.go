@@ -0,0 +1,91 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package argon3
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultPoolMaxBytes is the default ceiling on how many bytes of memory
+// blocks this package retains in its internal sync.Pool across calls. See
+// SetPoolMaxBytes.
+const defaultPoolMaxBytes = 256 * 1024 * 1024
+
+var (
+	poolMaxBytes  int64 = defaultPoolMaxBytes
+	poolBytesHeld int64
+	blockPools    sync.Map // map[uint32]*sync.Pool, keyed by blockBucket(memory)
+)
+
+// SetPoolMaxBytes caps the total size of the []block buffers that
+// Key/IDKey/DKey (and their *WithSecret and *Context variants) retain in an
+// internal sync.Pool between calls, in bytes. Lower it on memory-
+// constrained hosts to bound memory held between logins at the cost of more
+// allocations per call; raise it on servers that can spare the RAM. The
+// cap is advisory: the Go runtime may still evict pooled buffers at any
+// time, e.g. during GC.
+func SetPoolMaxBytes(n int64) {
+	atomic.StoreInt64(&poolMaxBytes, n)
+}
+
+// blockBucket rounds memory up to the next power of two, so a small,
+// bounded set of sync.Pools is shared across the range of memory
+// parameters callers use instead of growing one pool per exact size.
+//
+// The search is done in uint64 since the next power of two above a
+// memory value near math.MaxUint32 does not itself fit in a uint32; in
+// that case there is no pooling benefit to be had anyway, so the exact
+// value is returned unrounded instead of overflowing into an infinite
+// loop.
+func blockBucket(memory uint32) uint32 {
+	bucket := uint64(1)
+	m := uint64(memory)
+	for bucket < m {
+		bucket <<= 1
+	}
+	if bucket > math.MaxUint32 {
+		return memory
+	}
+	return uint32(bucket)
+}
+
+func poolFor(bucket uint32) *sync.Pool {
+	if p, ok := blockPools.Load(bucket); ok {
+		return p.(*sync.Pool)
+	}
+	p, _ := blockPools.LoadOrStore(bucket, new(sync.Pool))
+	return p.(*sync.Pool)
+}
+
+// getBlocks returns a []block of length memory, drawn from the pool when
+// possible to avoid a fresh make([]block, memory) on every call.
+func getBlocks(memory uint32) []block {
+	bucket := blockBucket(memory)
+	if v := poolFor(bucket).Get(); v != nil {
+		B := v.([]block)
+		atomic.AddInt64(&poolBytesHeld, -int64(cap(B))*blockLength*8)
+		return B[:memory]
+	}
+	return make([]block, memory, bucket)
+}
+
+// putBlocks zeroes B, since it may still hold key-derived state, and
+// returns its backing array to the pool, subject to the poolMaxBytes
+// budget.
+func putBlocks(B []block) {
+	full := B[:cap(B)]
+	for i := range full {
+		full[i] = block{}
+	}
+
+	size := int64(cap(full)) * blockLength * 8
+	if atomic.AddInt64(&poolBytesHeld, size) > atomic.LoadInt64(&poolMaxBytes) {
+		atomic.AddInt64(&poolBytesHeld, -size)
+		return
+	}
+	poolFor(uint32(cap(full))).Put(full)
+}
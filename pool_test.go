@@ -0,0 +1,96 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package argon3
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBlockBucket(t *testing.T) {
+	cases := map[uint32]uint32{
+		0:    1,
+		1:    1,
+		2:    2,
+		3:    4,
+		64:   64,
+		65:   128,
+		1024: 1024,
+		1025: 2048,
+	}
+	for memory, want := range cases {
+		if got := blockBucket(memory); got != want {
+			t.Errorf("blockBucket(%d) = %d, want %d", memory, got, want)
+		}
+	}
+}
+
+// TestBlockBucketHugeMemoryDoesNotHang guards against the uint32 doubling
+// loop overflowing to 0 and spinning forever for memory values whose next
+// power of two doesn't fit in a uint32.
+func TestBlockBucketHugeMemoryDoesNotHang(t *testing.T) {
+	done := make(chan uint32, 1)
+	go func() { done <- blockBucket(3_000_000_000) }()
+
+	select {
+	case got := <-done:
+		if got < 3_000_000_000 {
+			t.Errorf("blockBucket(3_000_000_000) = %d, want >= memory", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("blockBucket(3_000_000_000) did not return; overflow loop likely spinning")
+	}
+
+	if got := blockBucket(math.MaxUint32); got != math.MaxUint32 {
+		t.Errorf("blockBucket(MaxUint32) = %d, want %d", got, uint32(math.MaxUint32))
+	}
+}
+
+func TestPoolReuse(t *testing.T) {
+	defer SetPoolMaxBytes(defaultPoolMaxBytes)
+
+	const memory = 64
+	B := getBlocks(memory)
+	backing := &B[0]
+	putBlocks(B)
+
+	B2 := getBlocks(memory)
+	if &B2[0] != backing {
+		t.Errorf("getBlocks did not reuse the buffer returned by putBlocks")
+	}
+	putBlocks(B2)
+}
+
+func TestSetPoolMaxBytesRejectsOversizedBuffers(t *testing.T) {
+	defer SetPoolMaxBytes(defaultPoolMaxBytes)
+	SetPoolMaxBytes(0)
+
+	const memory = 64
+	B := getBlocks(memory)
+	backing := &B[0]
+	putBlocks(B)
+
+	B2 := getBlocks(memory)
+	if &B2[0] == backing {
+		t.Errorf("putBlocks retained a buffer despite a zero poolMaxBytes budget")
+	}
+}
+
+func benchmarkArgon3Allocs(mode int, b *testing.B) {
+	password, salt := []byte("password"), []byte("somesalt")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		deriveKey(mode, password, salt, nil, nil, 1, 64*1024, 4, 32)
+	}
+}
+
+// BenchmarkArgon3idAllocs tracks allocs/op for a single IDKey call at the
+// recommended 64 MiB parameters; pooling the []block buffer in
+// initBlocks/extractKey should keep this to a handful of allocations
+// instead of one 64 MiB allocation per call.
+func BenchmarkArgon3idAllocs(b *testing.B) {
+	benchmarkArgon3Allocs(argon3id, b)
+}